@@ -0,0 +1,61 @@
+package charunit
+
+import (
+	"context"
+	"fmt"
+
+	commonpb "go.viam.com/api/common/v1"
+	pb "go.viam.com/api/service/generic/v1"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/utils/rpc"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// client is the gRPC client side of a char-unit-load resource consumed
+// remotely over Viam RPC, e.g. by a machine hosting this module when driven
+// from viam-cli, another module, or an SDK client. It rides the
+// generic-service proto since DoCommand is the only method this service
+// exposes beyond the base resource.Resource surface.
+type client struct {
+	resource.Named
+	resource.TriviallyReconfigurable
+	resource.TriviallyCloseable
+
+	name   resource.Name
+	client pb.GenericServiceClient
+	logger logging.Logger
+}
+
+// newClientFromConn builds a char-unit-load client over conn, honoring
+// remoteName when building the resource's public name.
+func newClientFromConn(conn rpc.ClientConn, remoteName string, name resource.Name, logger logging.Logger) (resource.Resource, error) {
+	if remoteName != "" {
+		name = name.PrependRemote(remoteName)
+	}
+	return &client{
+		Named:  name.AsNamed(),
+		name:   name,
+		client: pb.NewGenericServiceClient(conn),
+		logger: logger,
+	}, nil
+}
+
+func (c *client) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	command, err := structpb.NewStruct(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("converting command to proto struct: %w", err)
+	}
+
+	resp, err := c.client.DoCommand(ctx, &commonpb.DoCommandRequest{
+		Name:    c.name.ShortName(),
+		Command: command,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Result == nil {
+		return nil, nil
+	}
+	return resp.Result.AsMap(), nil
+}
@@ -0,0 +1,30 @@
+package charunit
+
+import "errors"
+
+// Sentinel errors returned by DoCommand so callers can distinguish failure
+// modes with errors.Is instead of string-matching fmt.Errorf output.
+var (
+	// ErrMissingCharLoad is returned when a DoCommand map has no "char_load" key.
+	ErrMissingCharLoad = errors.New("char_load key is required")
+
+	// ErrUnknownCommand is returned when "char_load" names a command this
+	// service doesn't implement.
+	ErrUnknownCommand = errors.New("unknown char_load command")
+
+	// ErrInvalidArgument is returned when a DoCommand argument is missing,
+	// the wrong type, or out of range.
+	ErrInvalidArgument = errors.New("invalid argument")
+
+	// ErrRunAlreadyActive is returned by "start" and "run" when another run is already
+	// active and the config does not set allow_concurrent.
+	ErrRunAlreadyActive = errors.New("a run is already active; set allow_concurrent to true to run multiple simultaneously")
+
+	// ErrRunNotFound is returned by "stop" and "status" when run_id doesn't
+	// match a known run.
+	ErrRunNotFound = errors.New("run not found")
+
+	// ErrEstopped is returned by "start" and "run" while the service is
+	// latched off following an "estop", until an explicit "reset".
+	ErrEstopped = errors.New("service is e-stopped; send {\"char_load\": \"reset\"} to clear")
+)
@@ -0,0 +1,83 @@
+package charunit
+
+import (
+	"sync"
+	"time"
+)
+
+// maxEventLogLen bounds the in-memory event log so a machine that never
+// calls "subscribe" doesn't grow this without bound.
+const maxEventLogLen = 4096
+
+// stateEvent is one char-load run state transition, as published by emit
+// and drained by the "subscribe" DoCommand.
+type stateEvent struct {
+	Seq    int64
+	Time   time.Time
+	RunID  string
+	Phase  string
+	Pin    string
+	Detail string
+}
+
+func (e stateEvent) toMap() map[string]interface{} {
+	return map[string]interface{}{
+		"seq":    e.Seq,
+		"time":   e.Time,
+		"run_id": e.RunID,
+		"phase":  e.Phase,
+		"pin":    e.Pin,
+		"detail": e.Detail,
+	}
+}
+
+// eventLog is an in-process, cursor-addressable log of state events. It is
+// not a true pub/sub channel -- DoCommand is request/response, so
+// "subscribe" instead drains everything published since a caller-supplied
+// cursor, which lets multiple independent consumers poll at their own pace
+// without missing events between calls, as long as no more than
+// maxEventLogLen events are published between two polls; older events are
+// dropped.
+type eventLog struct {
+	mu      sync.Mutex
+	events  []stateEvent
+	nextSeq int64
+}
+
+func newEventLog() *eventLog {
+	return &eventLog{}
+}
+
+func (e *eventLog) publish(runID, phase, pin, detail string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	ev := stateEvent{
+		Seq:    e.nextSeq,
+		Time:   time.Now(),
+		RunID:  runID,
+		Phase:  phase,
+		Pin:    pin,
+		Detail: detail,
+	}
+	e.nextSeq++
+	e.events = append(e.events, ev)
+	if len(e.events) > maxEventLogLen {
+		e.events = e.events[len(e.events)-maxEventLogLen:]
+	}
+}
+
+// since returns every event with Seq > cursor, and the cursor a caller
+// should pass next time to pick up where this call left off.
+func (e *eventLog) since(cursor int64) ([]stateEvent, int64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var out []stateEvent
+	for _, ev := range e.events {
+		if ev.Seq > cursor {
+			out = append(out, ev)
+		}
+	}
+	return out, e.nextSeq - 1
+}
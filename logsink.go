@@ -0,0 +1,228 @@
+package charunit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/utils"
+)
+
+// LogEvent is a single structured record emitted during a char-load run.
+// It is shipped to whichever LogSink the module is configured with, in
+// addition to (not instead of) the normal RDK logger.
+type LogEvent struct {
+	Time       time.Time `json:"time"`
+	RunID      string    `json:"run_id"`
+	Pin        string    `json:"pin,omitempty"`
+	Phase      string    `json:"phase"`
+	DurationMS int64     `json:"duration_ms,omitempty"`
+	BoardName  string    `json:"board_name,omitempty"`
+	Message    string    `json:"message,omitempty"`
+}
+
+// LogSink receives structured log events for a char-load run. Implementations
+// must be safe for concurrent use.
+type LogSink interface {
+	Write(ev LogEvent) error
+	Close() error
+}
+
+// LogSinkConfig configures the LogSink a char-unit-load service ships its
+// structured run events to. Type selects the implementation; the remaining
+// fields are interpreted according to Type.
+type LogSinkConfig struct {
+	Type string `json:"type"`
+
+	// file sink
+	Path       string `json:"path,omitempty"`
+	MaxSizeMB  int    `json:"max_size_mb,omitempty"`
+	MaxAgeDays int    `json:"max_age_days,omitempty"`
+
+	// http sink
+	URL string `json:"url,omitempty"`
+}
+
+// Validate checks that the sink config is internally consistent for its Type.
+func (c *LogSinkConfig) Validate(path string) error {
+	switch c.Type {
+	case "stdout":
+	case "file":
+		if c.Path == "" {
+			return utils.NewConfigValidationFieldRequiredError(path, "log_sink.path")
+		}
+	case "http":
+		if c.URL == "" {
+			return utils.NewConfigValidationFieldRequiredError(path, "log_sink.url")
+		}
+	default:
+		return fmt.Errorf("%s: log_sink.type must be one of \"stdout\", \"file\", \"http\", got %q", path, c.Type)
+	}
+	return nil
+}
+
+// newLogSink builds the LogSink described by cfg. A nil cfg yields a stdout
+// sink so that structured events always go somewhere.
+func newLogSink(cfg *LogSinkConfig, logger logging.Logger) (LogSink, error) {
+	if cfg == nil {
+		return &stdoutLogSink{}, nil
+	}
+	switch cfg.Type {
+	case "", "stdout":
+		return &stdoutLogSink{}, nil
+	case "file":
+		return newRotatingFileLogSink(cfg.Path, cfg.MaxSizeMB, cfg.MaxAgeDays)
+	case "http":
+		return &httpLogSink{
+			url:    cfg.URL,
+			client: &http.Client{Timeout: 5 * time.Second},
+			logger: logger,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown log_sink.type %q", cfg.Type)
+	}
+}
+
+type stdoutLogSink struct {
+	mu sync.Mutex
+}
+
+func (s *stdoutLogSink) Write(ev LogEvent) error {
+	b, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = fmt.Fprintln(os.Stdout, string(b))
+	return err
+}
+
+func (s *stdoutLogSink) Close() error { return nil }
+
+// httpLogSink ships each LogEvent as its own JSON-lines POST body. Send
+// failures are returned as errors rather than retried. Each POST is
+// synchronous and bounded by the client's timeout, so a struggling log
+// endpoint can slow a char-load run but does not crash it.
+type httpLogSink struct {
+	url    string
+	client *http.Client
+	logger logging.Logger
+}
+
+func (h *httpLogSink) Write(ev LogEvent) error {
+	b, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	resp, err := h.client.Post(h.url, "application/jsonlines", bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("posting log event to %s: %w", h.url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("log sink %s responded with status %s", h.url, resp.Status)
+	}
+	return nil
+}
+
+func (h *httpLogSink) Close() error { return nil }
+
+// rotatingFileLogSink appends JSON-lines log events to a file, rotating it
+// once it exceeds maxSizeMB or maxAgeDays so long-running machines don't fill
+// disk. A maxSizeMB or maxAgeDays of 0 disables that trigger.
+type rotatingFileLogSink struct {
+	mu         sync.Mutex
+	path       string
+	maxSizeMB  int
+	maxAgeDays int
+
+	f        *os.File
+	size     int64
+	openedAt time.Time
+}
+
+func newRotatingFileLogSink(path string, maxSizeMB, maxAgeDays int) (*rotatingFileLogSink, error) {
+	s := &rotatingFileLogSink{
+		path:       path,
+		maxSizeMB:  maxSizeMB,
+		maxAgeDays: maxAgeDays,
+	}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *rotatingFileLogSink) open() error {
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening log file %s: %w", s.path, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	s.f = f
+	s.size = info.Size()
+	s.openedAt = time.Now()
+	return nil
+}
+
+func (s *rotatingFileLogSink) Write(ev LogEvent) error {
+	b, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.shouldRotateLocked() {
+		if err := s.rotateLocked(); err != nil {
+			return err
+		}
+	}
+
+	n, err := s.f.Write(b)
+	s.size += int64(n)
+	return err
+}
+
+func (s *rotatingFileLogSink) shouldRotateLocked() bool {
+	if s.maxSizeMB > 0 && s.size >= int64(s.maxSizeMB)*1024*1024 {
+		return true
+	}
+	if s.maxAgeDays > 0 && time.Since(s.openedAt) >= time.Duration(s.maxAgeDays)*24*time.Hour {
+		return true
+	}
+	return false
+}
+
+func (s *rotatingFileLogSink) rotateLocked() error {
+	if s.f != nil {
+		s.f.Close()
+	}
+	rotatedPath := fmt.Sprintf("%s.%s", s.path, time.Now().Format("20060102T150405"))
+	if err := os.Rename(s.path, rotatedPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("rotating log file %s: %w", s.path, err)
+	}
+	return s.open()
+}
+
+func (s *rotatingFileLogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		return nil
+	}
+	return s.f.Close()
+}
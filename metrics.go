@@ -0,0 +1,119 @@
+package charunit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.viam.com/rdk/logging"
+)
+
+// metrics holds the Prometheus collectors for a char-unit-load service and,
+// if MetricsAddr is configured, an HTTP server exposing them at /metrics.
+type metrics struct {
+	runsTotal   prometheus.Counter
+	errorsTotal prometheus.Counter
+	runDuration prometheus.Histogram
+	pinsHigh    prometheus.Gauge
+
+	mu         sync.Mutex
+	pinHighSet map[string]bool
+	server     *http.Server
+}
+
+// newMetrics builds a fresh collector set. If addr is empty, metrics are
+// still recorded in-process but not served over HTTP.
+func newMetrics(addr string, logger logging.Logger) (*metrics, error) {
+	reg := prometheus.NewRegistry()
+
+	m := &metrics{
+		runsTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "char_load_runs_total",
+			Help: "Total number of char-load runs started, across \"start\" and \"run\".",
+		}),
+		errorsTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "char_load_errors_total",
+			Help: "Total number of char-load runs that ended in phaseFailed.",
+		}),
+		runDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "char_load_run_duration_seconds",
+			Help:    "Distribution of char-load run durations, from start to final phase.",
+			Buckets: prometheus.DefBuckets,
+		}),
+		pinsHigh: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "char_load_pins_high",
+			Help: "Number of pins currently driven high by this char-load service.",
+		}),
+		pinHighSet: map[string]bool{},
+	}
+
+	if err := reg.Register(m.runsTotal); err != nil {
+		return nil, fmt.Errorf("registering char_load_runs_total: %w", err)
+	}
+	if err := reg.Register(m.errorsTotal); err != nil {
+		return nil, fmt.Errorf("registering char_load_errors_total: %w", err)
+	}
+	if err := reg.Register(m.runDuration); err != nil {
+		return nil, fmt.Errorf("registering char_load_run_duration_seconds: %w", err)
+	}
+	if err := reg.Register(m.pinsHigh); err != nil {
+		return nil, fmt.Errorf("registering char_load_pins_high: %w", err)
+	}
+
+	if addr == "" {
+		return m, nil
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+	m.server = &http.Server{Addr: addr, Handler: mux}
+	go func() {
+		if err := m.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Errorf("char-load metrics server on %s stopped: %v", addr, err)
+		}
+	}()
+
+	return m, nil
+}
+
+func (m *metrics) recordRunStart() {
+	m.runsTotal.Inc()
+}
+
+func (m *metrics) recordRunError() {
+	m.errorsTotal.Inc()
+}
+
+func (m *metrics) recordRunDuration(d time.Duration) {
+	m.runDuration.Observe(d.Seconds())
+}
+
+// setPinHigh updates the currently-high pin gauge, counting each pin at most
+// once regardless of how many runs are driving it.
+func (m *metrics) setPinHigh(pin string, high bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.pinHighSet[pin] == high {
+		return
+	}
+	m.pinHighSet[pin] = high
+	if high {
+		m.pinsHigh.Inc()
+	} else {
+		m.pinsHigh.Dec()
+	}
+}
+
+func (m *metrics) Close() error {
+	if m.server == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return m.server.Shutdown(ctx)
+}
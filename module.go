@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"go.viam.com/rdk/components/board"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/resource"
@@ -29,20 +31,27 @@ func init() {
 
 type Config struct {
 	Board string `json:"board"`
-	/*
-		Put config attributes here. There should be public/exported fields
-		with a `json` parameter at the end of each attribute.
-
-		Example config struct:
-			type Config struct {
-				Pin   string `json:"pin"`
-				Board string `json:"board"`
-				MinDeg *float64 `json:"min_angle_deg,omitempty"`
-			}
 
-		If your model does not need a config, replace *Config in the init
-		function with resource.NoNativeConfig
-	*/
+	// LogSink configures where structured per-run log events are shipped, in
+	// addition to the normal RDK logger. Defaults to stdout if omitted.
+	LogSink *LogSinkConfig `json:"log_sink,omitempty"`
+
+	// AllowConcurrent permits a "start" command while another run is still
+	// active. Defaults to false, i.e. only one run may be active at a time.
+	AllowConcurrent bool `json:"allow_concurrent,omitempty"`
+
+	// Recipes are named, ordered pin sequences runnable via
+	// {"char_load": "run", "recipe": "<name>"}.
+	Recipes []Recipe `json:"recipes,omitempty"`
+
+	// MaxRunDuration caps how long any single run (a "start" or a "run") may
+	// stay active before a watchdog force-drives its pins low and fails it.
+	// Zero disables the watchdog.
+	MaxRunDuration float64 `json:"max_run_duration_sec,omitempty"`
+
+	// MetricsAddr, if set, serves Prometheus metrics (e.g. ":9100") for this
+	// service. Metrics are still recorded in-process if left empty.
+	MetricsAddr string `json:"metrics_addr,omitempty"`
 }
 
 // Validate ensures all parts of the config are valid and important fields exist.
@@ -55,9 +64,42 @@ func (cfg *Config) Validate(path string) ([]string, error) {
 	if cfg.Board == "" {
 		return nil, utils.NewConfigValidationFieldRequiredError(path, "board")
 	}
+
+	if cfg.LogSink != nil {
+		if err := cfg.LogSink.Validate(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.MaxRunDuration < 0 {
+		return nil, fmt.Errorf("%s: max_run_duration_sec must be >= 0", path)
+	}
+
+	seenRecipes := map[string]bool{}
+	for i := range cfg.Recipes {
+		r := &cfg.Recipes[i]
+		if err := r.validate(path); err != nil {
+			return nil, err
+		}
+		if seenRecipes[r.Name] {
+			return nil, fmt.Errorf("%s: duplicate recipe name %q", path, r.Name)
+		}
+		seenRecipes[r.Name] = true
+	}
+
 	return deps, nil
 }
 
+// findRecipe looks up a configured recipe by name.
+func (s *charUnitCharUnitLoad) findRecipe(name string) (*Recipe, bool) {
+	for i := range s.cfg.Recipes {
+		if s.cfg.Recipes[i].Name == name {
+			return &s.cfg.Recipes[i], true
+		}
+	}
+	return nil, false
+}
+
 type charUnitCharUnitLoad struct {
 	resource.AlwaysRebuild
 
@@ -69,7 +111,77 @@ type charUnitCharUnitLoad struct {
 	cancelCtx  context.Context
 	cancelFunc func()
 
-	b board.Board
+	b        board.Board
+	logSink  LogSink
+	registry *runRegistry
+	metrics  *metrics
+	events   *eventLog
+
+	// wg tracks in-flight run goroutines so Close can wait for their pin
+	// cleanup before returning.
+	wg sync.WaitGroup
+
+	estopMu  sync.Mutex
+	estopped bool
+
+	// startMu serializes the allow_concurrent admission check against
+	// registry.add so two concurrent start/run calls can't both pass the
+	// check before either registers.
+	startMu sync.Mutex
+}
+
+// admitRun enforces AllowConcurrent and, if the run is admitted, registers
+// rs atomically with that check so a racing caller can't slip past it.
+func (s *charUnitCharUnitLoad) admitRun(rs *runState) error {
+	s.startMu.Lock()
+	defer s.startMu.Unlock()
+
+	if !s.cfg.AllowConcurrent && s.registry.activeCount() > 0 {
+		return ErrRunAlreadyActive
+	}
+	s.registry.add(rs)
+	return nil
+}
+
+func (s *charUnitCharUnitLoad) isEstopped() bool {
+	s.estopMu.Lock()
+	defer s.estopMu.Unlock()
+	return s.estopped
+}
+
+func (s *charUnitCharUnitLoad) setEstopped(v bool) {
+	s.estopMu.Lock()
+	defer s.estopMu.Unlock()
+	s.estopped = v
+}
+
+// allKnownPins returns every pin this service knows how to drive: the
+// default single-pin start target, every pin referenced by a configured
+// recipe, and any pin touched by a currently-tracked run. Used by estop to
+// make sure nothing is left energized.
+func (s *charUnitCharUnitLoad) allKnownPins() []string {
+	seen := map[string]bool{}
+	var out []string
+	add := func(pin string) {
+		if pin == "" || seen[pin] {
+			return
+		}
+		seen[pin] = true
+		out = append(out, pin)
+	}
+
+	add(defaultPin)
+	for i := range s.cfg.Recipes {
+		for _, pin := range s.cfg.Recipes[i].pins() {
+			add(pin)
+		}
+	}
+	for _, rs := range s.registry.list() {
+		for _, pin := range rs.knownPins() {
+			add(pin)
+		}
+	}
+	return out
 }
 
 func newCharUnitCharUnitLoad(ctx context.Context, deps resource.Dependencies, rawConf resource.Config, logger logging.Logger) (resource.Resource, error) {
@@ -94,6 +206,8 @@ func NewCharUnitLoad(ctx context.Context, deps resource.Dependencies, name resou
 		cfg:        conf,
 		cancelCtx:  cancelCtx,
 		cancelFunc: cancelFunc,
+		registry:   newRunRegistry(),
+		events:     newEventLog(),
 	}
 
 	if err := s.Reconfigure(ctx, deps, rawConf); err != nil {
@@ -108,69 +222,417 @@ func (s *charUnitCharUnitLoad) Name() resource.Name {
 }
 
 func (s *charUnitCharUnitLoad) NewClientFromConn(ctx context.Context, conn rpc.ClientConn, remoteName string, name resource.Name, logger logging.Logger) (resource.Resource, error) {
-	panic("not implemented")
+	return newClientFromConn(conn, remoteName, name, logger)
 }
 
 func (s *charUnitCharUnitLoad) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
-	// m.logger.Infof("DoCommand called with cmd=%v", cmd)
-	for key, value := range cmd {
-		switch key {
-		// "TurnThenCenter": "SmallLeft"
-		case "char_load":
-			s.logger.Infof("DoCommand key=%v", key)
-			command := value.(string)
-			s.logger.Infof("DoCommand command=%v", command)
-			switch command {
-			case "start":
-				go bioCharProcess(s)
-			default:
-				return nil, fmt.Errorf("unknown DoCommand value for %v = %v", key, value)
-			}
+	raw, ok := cmd["char_load"]
+	if !ok {
+		return nil, ErrMissingCharLoad
+	}
+	command, ok := raw.(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: char_load must be a string", ErrInvalidArgument)
+	}
+
+	switch command {
+	case "start":
+		return s.doStart(cmd)
+	case "stop":
+		return s.doStop(cmd)
+	case "status":
+		return s.doStatus(cmd)
+	case "list_runs":
+		return s.doListRuns(cmd)
+	case "run":
+		return s.doRun(cmd)
+	case "estop":
+		return s.doEstop(cmd)
+	case "reset":
+		return s.doReset(cmd)
+	case "subscribe":
+		return s.doSubscribe(cmd)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownCommand, command)
+	}
+}
 
-			return nil, nil
-		default:
-			return nil, fmt.Errorf("unknown DoCommand key = %v ", key)
+func stringArg(cmd map[string]interface{}, key, def string) (string, error) {
+	v, ok := cmd[key]
+	if !ok {
+		return def, nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("%w: %s must be a string", ErrInvalidArgument, key)
+	}
+	return s, nil
+}
+
+func (s *charUnitCharUnitLoad) doSubscribe(cmd map[string]interface{}) (map[string]interface{}, error) {
+	cursor := int64(-1)
+	if v, ok := cmd["cursor"]; ok {
+		f, ok := v.(float64)
+		if !ok {
+			return nil, fmt.Errorf("%w: cursor must be a number", ErrInvalidArgument)
 		}
+		cursor = int64(f)
 	}
-	return nil, fmt.Errorf("unknown DoCommand command map: %v", cmd)
+
+	events, newCursor := s.events.since(cursor)
+	out := make([]map[string]interface{}, len(events))
+	for i, ev := range events {
+		out[i] = ev.toMap()
+	}
+	return map[string]interface{}{"events": out, "cursor": newCursor}, nil
 }
 
-func bioCharProcess(s *charUnitCharUnitLoad) {
-	if s.b == nil {
-		s.logger.Error("s.b (Board component) is nil inside DoCommand")
+func (s *charUnitCharUnitLoad) doEstop(cmd map[string]interface{}) (map[string]interface{}, error) {
+	pins := s.allKnownPins()
+	s.drivePinsLow(pins)
+
+	for _, rs := range s.registry.list() {
+		if rs.isActive() {
+			rs.cancel()
+		}
+	}
+	s.setEstopped(true)
+
+	return map[string]interface{}{"estopped": true, "pins": pins}, nil
+}
+
+func (s *charUnitCharUnitLoad) doReset(cmd map[string]interface{}) (map[string]interface{}, error) {
+	s.setEstopped(false)
+	return map[string]interface{}{"estopped": false}, nil
+}
+
+func (s *charUnitCharUnitLoad) doStart(cmd map[string]interface{}) (map[string]interface{}, error) {
+	if s.isEstopped() {
+		return nil, ErrEstopped
+	}
+
+	pin, err := stringArg(cmd, "pin", defaultPin)
+	if err != nil {
+		return nil, err
+	}
+
+	durationSec := 60.0
+	if v, ok := cmd["duration_sec"]; ok {
+		d, ok := v.(float64)
+		if !ok {
+			return nil, fmt.Errorf("%w: duration_sec must be a number", ErrInvalidArgument)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("%w: duration_sec must be > 0", ErrInvalidArgument)
+		}
+		durationSec = d
+	}
+
+	runID, err := stringArg(cmd, "run_id", "")
+	if err != nil {
+		return nil, err
+	}
+	if runID == "" {
+		runID = uuid.New().String()
+	}
+
+	async := false
+	if v, ok := cmd["async"]; ok {
+		a, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%w: async must be a bool", ErrInvalidArgument)
+		}
+		async = a
+	}
+
+	runCtx, runCancel := context.WithCancel(s.cancelCtx)
+	rs := newRunState(runID, pin, runCancel)
+	if err := s.admitRun(rs); err != nil {
+		runCancel()
+		return nil, err
+	}
+
+	if s.cfg.MaxRunDuration > 0 {
+		go s.watchdog(runCtx, rs, []string{pin}, time.Duration(s.cfg.MaxRunDuration*float64(time.Second)))
+	}
+
+	if s.metrics != nil {
+		s.metrics.recordRunStart()
+	}
+
+	s.wg.Add(1)
+	run := func() {
+		defer s.wg.Done()
+		defer runCancel()
+		bioCharProcess(s, runCtx, rs, time.Duration(durationSec*float64(time.Second)))
+	}
+	if async {
+		go run()
+		return rs.snapshot(), nil
+	}
+	run()
+	return rs.snapshot(), nil
+}
+
+func (s *charUnitCharUnitLoad) doRun(cmd map[string]interface{}) (map[string]interface{}, error) {
+	if s.isEstopped() {
+		return nil, ErrEstopped
+	}
+
+	recipeName, err := stringArg(cmd, "recipe", "")
+	if err != nil {
+		return nil, err
+	}
+	if recipeName == "" {
+		return nil, fmt.Errorf("%w: recipe is required", ErrInvalidArgument)
+	}
+	recipe, ok := s.findRecipe(recipeName)
+	if !ok {
+		return nil, fmt.Errorf("%w: recipe %q not found", ErrInvalidArgument, recipeName)
+	}
+
+	runID, err := stringArg(cmd, "run_id", "")
+	if err != nil {
+		return nil, err
+	}
+	if runID == "" {
+		runID = uuid.New().String()
+	}
+
+	async := false
+	if v, ok := cmd["async"]; ok {
+		a, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%w: async must be a bool", ErrInvalidArgument)
+		}
+		async = a
+	}
+
+	runCtx, runCancel := context.WithCancel(s.cancelCtx)
+	rs := newRecipeRunState(runID, recipeName, runCancel)
+	if err := s.admitRun(rs); err != nil {
+		runCancel()
+		return nil, err
+	}
+
+	if s.cfg.MaxRunDuration > 0 {
+		go s.watchdog(runCtx, rs, recipe.pins(), time.Duration(s.cfg.MaxRunDuration*float64(time.Second)))
+	}
+
+	if s.metrics != nil {
+		s.metrics.recordRunStart()
+	}
+
+	s.wg.Add(1)
+	run := func() {
+		defer s.wg.Done()
+		defer runCancel()
+		runRecipeProcess(s, runCtx, rs, recipe)
+	}
+	if async {
+		go run()
+		return rs.snapshot(), nil
+	}
+	run()
+	return rs.snapshot(), nil
+}
+
+// watchdog force-drives pins low and fails the run if it is still active
+// once maxDuration has elapsed, as a backstop independent of the run's own
+// cancellation handling (e.g. if that goroutine is wedged on a slow Set
+// call).
+func (s *charUnitCharUnitLoad) watchdog(ctx context.Context, rs *runState, pins []string, maxDuration time.Duration) {
+	select {
+	case <-ctx.Done():
 		return
+	case <-time.After(maxDuration):
 	}
-	// Get the GPIOPin with pin number 11
-	pin, err := s.b.GPIOPinByName("11")
+
+	if !rs.isActive() {
+		return
+	}
+
+	s.logger.With("run_id", rs.RunID).Errorf("exceeded max_run_duration_sec of %v; force-driving pins low", maxDuration)
+	s.drivePinsLow(pins)
+	s.finishRun(rs, phaseFailed, fmt.Errorf("exceeded max_run_duration_sec of %v", maxDuration))
+	rs.cancel()
+}
+
+func (s *charUnitCharUnitLoad) doStop(cmd map[string]interface{}) (map[string]interface{}, error) {
+	runID, err := stringArg(cmd, "run_id", "")
 	if err != nil {
-		s.logger.Error(err)
+		return nil, err
+	}
+	if runID == "" {
+		return nil, fmt.Errorf("%w: run_id is required", ErrInvalidArgument)
+	}
+
+	rs, ok := s.registry.get(runID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrRunNotFound, runID)
+	}
+	rs.cancel()
+	return rs.snapshot(), nil
+}
+
+func (s *charUnitCharUnitLoad) doStatus(cmd map[string]interface{}) (map[string]interface{}, error) {
+	runID, err := stringArg(cmd, "run_id", "")
+	if err != nil {
+		return nil, err
+	}
+	if runID == "" {
+		return nil, fmt.Errorf("%w: run_id is required", ErrInvalidArgument)
+	}
+
+	rs, ok := s.registry.get(runID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrRunNotFound, runID)
+	}
+	return rs.snapshot(), nil
+}
+
+func (s *charUnitCharUnitLoad) doListRuns(cmd map[string]interface{}) (map[string]interface{}, error) {
+	runs := s.registry.list()
+	out := make([]map[string]interface{}, len(runs))
+	for i, rs := range runs {
+		out[i] = rs.snapshot()
+	}
+	return map[string]interface{}{"runs": out}, nil
+}
+
+const defaultPin = "11"
+
+// emit logs a structured event for runID to both the normal RDK logger and
+// the configured LogSink, so operators can grep a whole char-load run across
+// the machine by run_id. It also appends to the in-process event log that
+// "subscribe" drains.
+func (s *charUnitCharUnitLoad) emit(runID, phase, pin string, durationMS int64, msg string) {
+	s.logger.With(
+		"run_id", runID,
+		"pin", pin,
+		"phase", phase,
+		"duration_ms", durationMS,
+		"board", s.b.Name().Name,
+	).Info(msg)
+
+	s.events.publish(runID, phase, pin, msg)
+
+	if s.logSink == nil {
 		return
 	}
-	s.logger.Infof("DoCommand fetched the pin successfully")
+	ev := LogEvent{
+		Time:       time.Now(),
+		RunID:      runID,
+		Pin:        pin,
+		Phase:      phase,
+		DurationMS: durationMS,
+		BoardName:  s.b.Name().Name,
+		Message:    msg,
+	}
+	if err := s.logSink.Write(ev); err != nil {
+		s.logger.Warnf("failed to write log event to sink: %v", err)
+	}
+}
+
+// setPinHigh records a pin's state on both the run and the pins-high gauge.
+func (s *charUnitCharUnitLoad) setPinHigh(rs *runState, pin string, high bool) {
+	rs.setPinHigh(pin, high)
+	if s.metrics != nil {
+		s.metrics.setPinHigh(pin, high)
+	}
+}
 
-	// Set the pin to high.
-	err = pin.Set(context.Background(), true, nil)
+// finishRun finalizes a run's phase and records its outcome in metrics.
+func (s *charUnitCharUnitLoad) finishRun(rs *runState, phase runPhase, err error) {
+	if !rs.finish(phase, err) || s.metrics == nil {
+		return
+	}
+	s.metrics.recordRunDuration(time.Since(rs.StartedAt))
+	if phase == phaseFailed {
+		s.metrics.recordRunError()
+	}
+}
+
+func bioCharProcess(s *charUnitCharUnitLoad, ctx context.Context, rs *runState, duration time.Duration) {
+	runID := rs.RunID
+	rs.setPhase(phaseRunning)
+	runLogger := s.logger.With("run_id", runID, "pin", rs.Pin)
+
+	if s.b == nil {
+		runLogger.Error("s.b (Board component) is nil inside DoCommand")
+		s.finishRun(rs, phaseFailed, errors.New("board not configured"))
+		return
+	}
+	pin, err := s.b.GPIOPinByName(rs.Pin)
 	if err != nil {
-		s.logger.Error(err)
+		runLogger.Error(err)
+		s.finishRun(rs, phaseFailed, err)
 		return
 	}
-	s.logger.Infof("DoCommand set the pin to high successfully")
+	s.emit(runID, "fetched_pin", rs.Pin, 0, "fetched the pin successfully")
 
-	time.Sleep(60 * time.Second)
-	s.logger.Infof("DoCommand finished sleeping")
+	start := time.Now()
 
 	// Set the pin to high.
-	err = pin.Set(context.Background(), false, nil)
-	if err != nil {
-		s.logger.Error(err)
+	if err := pin.Set(context.Background(), true, nil); err != nil {
+		runLogger.Error(err)
+		s.finishRun(rs, phaseFailed, err)
 		return
 	}
-	s.logger.Infof("DoCommand set the pin to low successfully")
+	s.setPinHigh(rs, rs.Pin, true)
+	s.emit(runID, "pin_high", rs.Pin, 0, "set the pin to high successfully")
+
+	// Always drive the pin low on the way out, whether we ran to completion,
+	// were stopped, or the service is closing (Close cancels ctx).
+	defer func() {
+		if err := pin.Set(context.Background(), false, nil); err != nil {
+			runLogger.Error(err)
+			s.finishRun(rs, phaseFailed, err)
+			return
+		}
+		s.setPinHigh(rs, rs.Pin, false)
+		s.emit(runID, "pin_low", rs.Pin, time.Since(start).Milliseconds(), "set the pin to low successfully")
+
+		if ctx.Err() != nil {
+			s.finishRun(rs, phaseStopped, nil)
+			return
+		}
+		s.finishRun(rs, phaseCompleted, nil)
+	}()
+
+	if utils.SelectContextOrWait(ctx, duration) {
+		s.emit(runID, "sleep_done", rs.Pin, time.Since(start).Milliseconds(), "finished sleeping")
+	} else {
+		s.emit(runID, "stopped", rs.Pin, time.Since(start).Milliseconds(), "run stopped before duration elapsed")
+	}
 }
 
+// closeCleanupTimeout bounds how long Close waits for in-flight runs to
+// drive their pins low before giving up.
+const closeCleanupTimeout = 10 * time.Second
+
 func (s *charUnitCharUnitLoad) Close(context.Context) error {
-	// Put close code here
 	s.cancelFunc()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(closeCleanupTimeout):
+		s.logger.Warnf("timed out after %v waiting for active char-load runs to finish cleanup", closeCleanupTimeout)
+	}
+
+	if s.metrics != nil {
+		if err := s.metrics.Close(); err != nil {
+			s.logger.Warnf("failed to close metrics server: %v", err)
+		}
+	}
+	if s.logSink != nil {
+		return s.logSink.Close()
+	}
 	return nil
 }
 
@@ -180,12 +642,45 @@ func (s *charUnitCharUnitLoad) Reconfigure(ctx context.Context, deps resource.De
 	if err != nil {
 		return err
 	}
+	s.cfg = conf
 
 	b, err := board.FromDependencies(deps, conf.Board)
 	if err != nil {
 		return fmt.Errorf("no source camera for transform pipeline  (%s): %w", conf.Board, err)
 	}
 	s.b = b
+
+	for i := range conf.Recipes {
+		for _, pin := range conf.Recipes[i].pins() {
+			if _, err := b.GPIOPinByName(pin); err != nil {
+				return fmt.Errorf("recipe %q references pin %q which does not resolve on board %q: %w",
+					conf.Recipes[i].Name, pin, conf.Board, err)
+			}
+		}
+	}
+
+	if s.logSink != nil {
+		if err := s.logSink.Close(); err != nil {
+			s.logger.Warnf("failed to close previous log sink: %v", err)
+		}
+	}
+	logSink, err := newLogSink(conf.LogSink, s.logger)
+	if err != nil {
+		return fmt.Errorf("building log sink: %w", err)
+	}
+	s.logSink = logSink
+
+	if s.metrics != nil {
+		if err := s.metrics.Close(); err != nil {
+			s.logger.Warnf("failed to close previous metrics server: %v", err)
+		}
+	}
+	m, err := newMetrics(conf.MetricsAddr, s.logger)
+	if err != nil {
+		return fmt.Errorf("building metrics: %w", err)
+	}
+	s.metrics = m
+
 	s.logger.Info("board is now configured to ", s.b.Name())
 	return nil
 }
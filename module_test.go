@@ -0,0 +1,213 @@
+package charunit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+// fakeBoard is a minimal board.Board that only implements the methods
+// char-unit-load actually calls (GPIOPinByName, Name); every other method is
+// inherited from the embedded nil board.Board and will panic if invoked,
+// which is fine since these tests never touch them.
+type fakeBoard struct {
+	board.Board
+	name resource.Name
+}
+
+func (f *fakeBoard) Name() resource.Name { return f.name }
+
+func (f *fakeBoard) GPIOPinByName(pin string) (board.GPIOPin, error) {
+	return &fakeGPIOPin{}, nil
+}
+
+// fakeGPIOPin is a no-op board.GPIOPin covering the subset of methods
+// applyStep/bioCharProcess call.
+type fakeGPIOPin struct {
+	board.GPIOPin
+}
+
+func (f *fakeGPIOPin) Set(ctx context.Context, high bool, extra map[string]interface{}) error {
+	return nil
+}
+
+func (f *fakeGPIOPin) SetPWMFreq(ctx context.Context, freqHz float64, extra map[string]interface{}) error {
+	return nil
+}
+
+func (f *fakeGPIOPin) SetPWM(ctx context.Context, dutyCyclePct float64, extra map[string]interface{}) error {
+	return nil
+}
+
+// newTestService builds a charUnitCharUnitLoad wired to a fakeBoard,
+// bypassing Reconfigure (and the real board.FromDependencies resolution it
+// requires) so DoCommand can be exercised directly.
+func newTestService(t *testing.T) *charUnitCharUnitLoad {
+	t.Helper()
+
+	cancelCtx, cancelFunc := context.WithCancel(context.Background())
+	t.Cleanup(cancelFunc)
+
+	boardName := resource.NewName(resource.APINamespaceRDK.WithComponentType("board"), "test-board")
+	return &charUnitCharUnitLoad{
+		name:   resource.NewName(resource.APINamespaceRDK.WithServiceType("generic"), "test-char-load"),
+		logger: logging.NewTestLogger(t),
+		cfg: &Config{
+			Recipes: []Recipe{{
+				Name:  "blink",
+				Steps: []RecipeStep{{Pin: "11", Action: "high", DurationSec: 0.01}},
+			}},
+		},
+		cancelCtx:  cancelCtx,
+		cancelFunc: cancelFunc,
+		b:          &fakeBoard{name: boardName},
+		registry:   newRunRegistry(),
+		events:     newEventLog(),
+	}
+}
+
+// waitForPhase polls status until the run reaches phase, failing the test if
+// it doesn't arrive within timeout.
+func waitForPhase(t *testing.T, s *charUnitCharUnitLoad, runID, phase string, timeout time.Duration) map[string]interface{} {
+	t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for {
+		resp, err := s.DoCommand(context.Background(), map[string]interface{}{
+			"char_load": "status",
+			"run_id":    runID,
+		})
+		if err != nil {
+			t.Fatalf("status: %v", err)
+		}
+		if resp["phase"] == phase {
+			return resp
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("run %s did not reach phase %q within %v, last phase %v", runID, phase, timeout, resp["phase"])
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestDoCommandStart(t *testing.T) {
+	s := newTestService(t)
+
+	resp, err := s.DoCommand(context.Background(), map[string]interface{}{
+		"char_load":    "start",
+		"pin":          "11",
+		"duration_sec": 0.01,
+		"run_id":       "start-run",
+	})
+	if err != nil {
+		t.Fatalf("DoCommand(start): %v", err)
+	}
+	if resp["phase"] != string(phaseCompleted) {
+		t.Errorf("phase = %v, want %q", resp["phase"], phaseCompleted)
+	}
+}
+
+func TestDoCommandStop(t *testing.T) {
+	s := newTestService(t)
+
+	_, err := s.DoCommand(context.Background(), map[string]interface{}{
+		"char_load":    "start",
+		"pin":          "11",
+		"duration_sec": 5.0,
+		"run_id":       "stop-run",
+		"async":        true,
+	})
+	if err != nil {
+		t.Fatalf("DoCommand(start): %v", err)
+	}
+
+	resp, err := s.DoCommand(context.Background(), map[string]interface{}{
+		"char_load": "stop",
+		"run_id":    "stop-run",
+	})
+	if err != nil {
+		t.Fatalf("DoCommand(stop): %v", err)
+	}
+	if resp["run_id"] != "stop-run" {
+		t.Errorf("stop run_id = %v, want %q", resp["run_id"], "stop-run")
+	}
+
+	waitForPhase(t, s, "stop-run", string(phaseStopped), time.Second)
+}
+
+func TestDoCommandRun(t *testing.T) {
+	s := newTestService(t)
+
+	resp, err := s.DoCommand(context.Background(), map[string]interface{}{
+		"char_load": "run",
+		"recipe":    "blink",
+		"run_id":    "recipe-run",
+	})
+	if err != nil {
+		t.Fatalf("DoCommand(run): %v", err)
+	}
+	if resp["phase"] != string(phaseCompleted) {
+		t.Errorf("phase = %v, want %q", resp["phase"], phaseCompleted)
+	}
+}
+
+func TestDoCommandEstopAndReset(t *testing.T) {
+	s := newTestService(t)
+
+	resp, err := s.DoCommand(context.Background(), map[string]interface{}{"char_load": "estop"})
+	if err != nil {
+		t.Fatalf("DoCommand(estop): %v", err)
+	}
+	if resp["estopped"] != true {
+		t.Errorf("estopped = %v, want true", resp["estopped"])
+	}
+
+	_, err = s.DoCommand(context.Background(), map[string]interface{}{
+		"char_load": "start",
+		"pin":       "11",
+	})
+	if !errors.Is(err, ErrEstopped) {
+		t.Errorf("DoCommand(start) after estop: err = %v, want ErrEstopped", err)
+	}
+
+	if _, err := s.DoCommand(context.Background(), map[string]interface{}{"char_load": "reset"}); err != nil {
+		t.Fatalf("DoCommand(reset): %v", err)
+	}
+	if _, err := s.DoCommand(context.Background(), map[string]interface{}{
+		"char_load":    "start",
+		"pin":          "11",
+		"duration_sec": 0.01,
+	}); err != nil {
+		t.Errorf("DoCommand(start) after reset: %v", err)
+	}
+}
+
+func TestDoCommandSubscribe(t *testing.T) {
+	s := newTestService(t)
+
+	if _, err := s.DoCommand(context.Background(), map[string]interface{}{
+		"char_load":    "start",
+		"pin":          "11",
+		"duration_sec": 0.01,
+		"run_id":       "subscribe-run",
+	}); err != nil {
+		t.Fatalf("DoCommand(start): %v", err)
+	}
+
+	resp, err := s.DoCommand(context.Background(), map[string]interface{}{
+		"char_load": "subscribe",
+		"cursor":    float64(-1),
+	})
+	if err != nil {
+		t.Fatalf("DoCommand(subscribe): %v", err)
+	}
+	events, ok := resp["events"].([]map[string]interface{})
+	if !ok || len(events) == 0 {
+		t.Errorf("subscribe returned %v events, want at least one", resp["events"])
+	}
+}
@@ -0,0 +1,176 @@
+package charunit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/utils"
+)
+
+// Recipe is a named, ordered sequence of pin actions that "run" executes
+// sequentially. Recipes are defined in Config and referenced by name from
+// DoCommand's {"char_load": "run", "recipe": "..."}.
+type Recipe struct {
+	Name  string       `json:"name"`
+	Steps []RecipeStep `json:"steps"`
+}
+
+// RecipeStep is one step of a Recipe: drive Pin to Action for DurationSec
+// seconds. PWMHz and PWMDuty are only meaningful when Action is "pwm".
+type RecipeStep struct {
+	Pin         string  `json:"pin"`
+	Action      string  `json:"action"`
+	DurationSec float64 `json:"duration_sec"`
+	PWMHz       float64 `json:"pwm_hz,omitempty"`
+	PWMDuty     float64 `json:"pwm_duty,omitempty"`
+}
+
+// pins returns the distinct pin names referenced anywhere in the recipe, in
+// first-seen order, so callers can drive all of them low on cancel/Close.
+func (r *Recipe) pins() []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, step := range r.Steps {
+		if seen[step.Pin] {
+			continue
+		}
+		seen[step.Pin] = true
+		out = append(out, step.Pin)
+	}
+	return out
+}
+
+// validate checks the recipe schema statically: pins are non-empty, actions
+// are recognized, durations are positive, PWM fields appear only alongside a
+// "pwm" action, and a "pwm" action has a positive pwm_hz and a pwm_duty
+// between 0 and 1 (an omitted pwm_duty is 0). It cannot confirm a pin resolves on
+// the configured board -- that requires the live board.Board and is checked
+// in Reconfigure instead. Whether a resolved pin's GPIOPin actually supports
+// PWM is not checked anywhere; a "pwm" step on a non-PWM-capable pin only
+// surfaces as a failed run when applyStep calls SetPWMFreq.
+func (r *Recipe) validate(path string) error {
+	if r.Name == "" {
+		return fmt.Errorf("%s: recipe name is required", path)
+	}
+	if len(r.Steps) == 0 {
+		return fmt.Errorf("%s: recipe %q must have at least one step", path, r.Name)
+	}
+	for i, step := range r.Steps {
+		if err := step.validate(fmt.Sprintf("%s.recipes.%s.steps.%d", path, r.Name, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *RecipeStep) validate(path string) error {
+	if s.Pin == "" {
+		return fmt.Errorf("%s: pin is required", path)
+	}
+	if s.DurationSec <= 0 {
+		return fmt.Errorf("%s: duration must be > 0", path)
+	}
+	switch s.Action {
+	case "high", "low":
+		if s.PWMHz != 0 || s.PWMDuty != 0 {
+			return fmt.Errorf("%s: pwm_hz/pwm_duty only apply to action \"pwm\"", path)
+		}
+	case "pwm":
+		if s.PWMHz <= 0 {
+			return fmt.Errorf("%s: pwm_hz must be > 0 for action \"pwm\"", path)
+		}
+		if s.PWMDuty < 0 || s.PWMDuty > 1 {
+			return fmt.Errorf("%s: pwm_duty must be between 0 and 1 for action \"pwm\"", path)
+		}
+	default:
+		return fmt.Errorf("%s: action must be one of \"high\", \"low\", \"pwm\", got %q", path, s.Action)
+	}
+	return nil
+}
+
+// drivePinsLow best-effort sets every named pin low. Used when a recipe run
+// ends (including on cancellation or Close), on estop, and on watchdog timeout
+// so a killed run doesn't leave pins energized -- and doesn't leave the
+// pins-high gauge stuck either.
+func (s *charUnitCharUnitLoad) drivePinsLow(pins []string) {
+	for _, name := range pins {
+		gp, err := s.b.GPIOPinByName(name)
+		if err != nil {
+			s.logger.Warnf("could not resolve pin %s while driving it low: %v", name, err)
+			continue
+		}
+		if err := gp.Set(context.Background(), false, nil); err != nil {
+			s.logger.Warnf("could not drive pin %s low: %v", name, err)
+			continue
+		}
+		if s.metrics != nil {
+			s.metrics.setPinHigh(name, false)
+		}
+	}
+}
+
+// runRecipeProcess executes recipe's steps in order against s.b, honoring
+// ctx cancellation between and during steps. On return -- whether completed,
+// cancelled, or failed -- every pin the recipe touches is driven low.
+func runRecipeProcess(s *charUnitCharUnitLoad, ctx context.Context, rs *runState, recipe *Recipe) {
+	rs.setPhase(phaseRunning)
+	runLogger := s.logger.With("run_id", rs.RunID, "recipe", recipe.Name)
+	pins := recipe.pins()
+	defer func() {
+		s.drivePinsLow(pins)
+		for _, pin := range pins {
+			rs.setPinHigh(pin, false)
+		}
+	}()
+
+	for i, step := range recipe.Steps {
+		if ctx.Err() != nil {
+			s.emit(rs.RunID, "stopped", step.Pin, 0, fmt.Sprintf("recipe %q stopped before step %d", recipe.Name, i))
+			s.finishRun(rs, phaseStopped, nil)
+			return
+		}
+
+		gp, err := s.b.GPIOPinByName(step.Pin)
+		if err != nil {
+			runLogger.With("pin", step.Pin).Error(err)
+			s.finishRun(rs, phaseFailed, err)
+			return
+		}
+
+		if err := applyStep(ctx, gp, step); err != nil {
+			runLogger.With("pin", step.Pin).Error(err)
+			s.finishRun(rs, phaseFailed, err)
+			return
+		}
+		s.setPinHigh(rs, step.Pin, step.Action != "low")
+		s.emit(rs.RunID, fmt.Sprintf("step_%d_%s", i, step.Action), step.Pin, 0,
+			fmt.Sprintf("recipe %q step %d (%s %s) started", recipe.Name, i, step.Action, step.Pin))
+
+		if !utils.SelectContextOrWait(ctx, time.Duration(step.DurationSec*float64(time.Second))) {
+			s.setPinHigh(rs, step.Pin, false)
+			s.emit(rs.RunID, "stopped", step.Pin, 0, fmt.Sprintf("recipe %q stopped during step %d", recipe.Name, i))
+			s.finishRun(rs, phaseStopped, nil)
+			return
+		}
+	}
+
+	s.finishRun(rs, phaseCompleted, nil)
+}
+
+func applyStep(ctx context.Context, gp board.GPIOPin, step RecipeStep) error {
+	switch step.Action {
+	case "high":
+		return gp.Set(ctx, true, nil)
+	case "low":
+		return gp.Set(ctx, false, nil)
+	case "pwm":
+		if err := gp.SetPWMFreq(ctx, step.PWMHz, nil); err != nil {
+			return fmt.Errorf("pin %s does not support pwm at %v Hz: %w", step.Pin, step.PWMHz, err)
+		}
+		return gp.SetPWM(ctx, step.PWMDuty, nil)
+	default:
+		return fmt.Errorf("unknown recipe step action %q", step.Action)
+	}
+}
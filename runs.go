@@ -0,0 +1,194 @@
+package charunit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// runPhase is the lifecycle state of a single char-load run.
+type runPhase string
+
+const (
+	phasePending   runPhase = "pending"
+	phaseRunning   runPhase = "running"
+	phaseCompleted runPhase = "completed"
+	phaseFailed    runPhase = "failed"
+	phaseStopped   runPhase = "stopped"
+)
+
+// runState tracks one char-load run in the in-memory run registry. The zero
+// value is not useful; construct with newRunState.
+type runState struct {
+	RunID     string
+	Pin       string
+	Recipe    string
+	StartedAt time.Time
+
+	mu        sync.Mutex
+	phase     runPhase
+	pinStates map[string]bool
+	endedAt   time.Time
+	err       error
+	cancel    context.CancelFunc
+}
+
+func newRunState(runID, pin string, cancel context.CancelFunc) *runState {
+	return &runState{
+		RunID:     runID,
+		Pin:       pin,
+		StartedAt: time.Now(),
+		phase:     phasePending,
+		pinStates: map[string]bool{},
+		cancel:    cancel,
+	}
+}
+
+func newRecipeRunState(runID, recipeName string, cancel context.CancelFunc) *runState {
+	return &runState{
+		RunID:     runID,
+		Recipe:    recipeName,
+		StartedAt: time.Now(),
+		phase:     phasePending,
+		pinStates: map[string]bool{},
+		cancel:    cancel,
+	}
+}
+
+func (r *runState) setPhase(p runPhase) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.phase = p
+}
+
+func (r *runState) setPinHigh(pin string, high bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pinStates[pin] = high
+}
+
+// finish records the run's final phase. Only the first call on an active run
+// takes effect; it reports whether this call was that one.
+func (r *runState) finish(p runPhase, err error) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.phase != phasePending && r.phase != phaseRunning {
+		return false
+	}
+	r.phase = p
+	r.err = err
+	r.endedAt = time.Now()
+	return true
+}
+
+// knownPins returns the pins this run has touched, including its primary
+// Pin (for simple start runs) even before any step has set its state.
+func (r *runState) knownPins() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	seen := map[string]bool{}
+	var out []string
+	if r.Pin != "" {
+		seen[r.Pin] = true
+		out = append(out, r.Pin)
+	}
+	for pin := range r.pinStates {
+		if seen[pin] {
+			continue
+		}
+		seen[pin] = true
+		out = append(out, pin)
+	}
+	return out
+}
+
+func (r *runState) isActive() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.phase == phasePending || r.phase == phaseRunning
+}
+
+// snapshot returns the DoCommand-facing view of the run's current state.
+func (r *runState) snapshot() map[string]interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	elapsed := time.Since(r.StartedAt)
+	if !r.endedAt.IsZero() {
+		elapsed = r.endedAt.Sub(r.StartedAt)
+	}
+
+	anyHigh := false
+	pins := make(map[string]bool, len(r.pinStates))
+	for pin, high := range r.pinStates {
+		pins[pin] = high
+		anyHigh = anyHigh || high
+	}
+
+	out := map[string]interface{}{
+		"run_id":      r.RunID,
+		"pin":         r.Pin,
+		"phase":       string(r.phase),
+		"elapsed_sec": elapsed.Seconds(),
+		"pin_high":    anyHigh,
+		"pins":        pins,
+	}
+	if r.Recipe != "" {
+		out["recipe"] = r.Recipe
+	}
+	if r.err != nil {
+		out["error"] = r.err.Error()
+	}
+	return out
+}
+
+// runRegistry is an in-memory, process-local record of char-load runs keyed
+// by run_id. It does not persist across restarts.
+type runRegistry struct {
+	mu   sync.Mutex
+	runs map[string]*runState
+}
+
+func newRunRegistry() *runRegistry {
+	return &runRegistry{runs: map[string]*runState{}}
+}
+
+func (r *runRegistry) add(rs *runState) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.runs[rs.RunID] = rs
+}
+
+func (r *runRegistry) get(runID string) (*runState, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rs, ok := r.runs[runID]
+	return rs, ok
+}
+
+func (r *runRegistry) list() []*runState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]*runState, 0, len(r.runs))
+	for _, rs := range r.runs {
+		out = append(out, rs)
+	}
+	return out
+}
+
+func (r *runRegistry) activeCount() int {
+	r.mu.Lock()
+	runs := make([]*runState, 0, len(r.runs))
+	for _, rs := range r.runs {
+		runs = append(runs, rs)
+	}
+	r.mu.Unlock()
+
+	n := 0
+	for _, rs := range runs {
+		if rs.isActive() {
+			n++
+		}
+	}
+	return n
+}